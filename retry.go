@@ -5,11 +5,76 @@
 package retry
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math/rand"
 	"time"
 )
 
+// Option configures optional behavior shared by Do, DoWithContext,
+// DoWithData and DoWithDataContext, such as retry/give-up notifications.
+type Option func(*options)
+
+// options holds the hooks and predicates configured through Option.
+type options struct {
+	onRetry   func(attempt int, err error, nextDelay time.Duration)
+	onGiveUp  func(attempt int, err error)
+	retryable func(err error) bool
+}
+
+// newOptions builds an options value from the given Option list.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// OnRetry registers a callback invoked after a failed attempt, before
+// waiting for the next one, with the attempt number, its error and the
+// delay before the next attempt.
+func OnRetry(fn func(attempt int, err error, nextDelay time.Duration)) Option {
+	return func(o *options) {
+		o.onRetry = fn
+	}
+}
+
+// OnGiveUp registers a callback invoked once retries stop for any reason
+// (attempts exhausted, a fatal or non-retryable error, or the context
+// being done), right before Do (or one of its variants) returns, with
+// the number of attempts made and the final error.
+func OnGiveUp(fn func(attempt int, err error)) Option {
+	return func(o *options) {
+		o.onGiveUp = fn
+	}
+}
+
+// Retryable registers a predicate deciding whether a non-fatal error
+// should trigger a retry. When pred returns false, the retry loop gives
+// up immediately instead of waiting for the next delay. A fatal error
+// (see EndRetry) always stops retries regardless of what pred returns.
+func Retryable(pred func(err error) bool) Option {
+	return func(o *options) {
+		o.retryable = pred
+	}
+}
+
+// RetryIf is an alias for Retryable, matching the naming used by other
+// retry libraries.
+func RetryIf(pred func(err error) bool) Option {
+	return Retryable(pred)
+}
+
+// AbortIf is the inverse of RetryIf: retries stop as soon as pred returns
+// true for the observed error.
+func AbortIf(pred func(err error) bool) Option {
+	return Retryable(func(err error) bool {
+		return !pred(err)
+	})
+}
+
 // retryStrategy defines the strategy pattern for retrying a function.
 type retryStrategy interface {
 	// GetDelay returns the delay for the given attempt.
@@ -17,10 +82,23 @@ type retryStrategy interface {
 
 	// GetAttempts returns the number of attempts.
 	GetAttempts() int
+
+	// Reset clears any state a strategy carries across attempts (e.g. an
+	// elapsed-time budget or a decorrelated jitter sequence), so the same
+	// strategy instance can be reused for a new, independent operation.
+	Reset()
 }
 
+// NoopReset is a no-op Reset, embeddable by retryStrategy implementations
+// that don't carry any state across attempts.
+type NoopReset struct{}
+
+// Reset does nothing.
+func (NoopReset) Reset() {}
+
 // LinearBackoff is a retry strategy that waits a fixed amount of time between each retry.
 type LinearBackoff struct {
+	NoopReset
 	retryDelay time.Duration
 	maxDelay   time.Duration
 	attempts   int
@@ -46,6 +124,7 @@ func (b LinearBackoff) GetAttempts() int {
 // ExponentialBackoff is a retry strategy that waits an
 // exponentially increasing amount of time between retries.
 type ExponentialBackoff struct {
+	NoopReset
 	retryDelay time.Duration
 	maxDelay   time.Duration
 	attempts   int
@@ -71,6 +150,7 @@ func (b ExponentialBackoff) GetAttempts() int {
 // RandomizedBackoff is a retry strategy that waits a
 // random amount of time between retries.
 type RandomizedBackoff struct {
+	NoopReset
 	retryDelay time.Duration
 	maxDelay   time.Duration
 	attempts   int
@@ -95,9 +175,108 @@ func (b RandomizedBackoff) GetAttempts() int {
 	return b.attempts
 }
 
+// JitterMode selects the jitter algorithm used by JitteredExponentialBackoff,
+// following the variants described in the AWS Architecture Blog post
+// "Exponential Backoff and Jitter".
+type JitterMode int
+
+const (
+	// JitterNone applies no jitter: delay grows exponentially, capped at max.
+	JitterNone JitterMode = iota
+
+	// JitterFull picks a random delay in [0, min(max, base<<attempt)).
+	JitterFull
+
+	// JitterEqual keeps half of the exponential delay fixed and randomizes
+	// the other half, trading off some jitter for a higher delay floor.
+	JitterEqual
+
+	// JitterDecorrelated grows each delay randomly off the previous one,
+	// capped at max. It avoids the thundering-herd effect better than
+	// JitterFull at the cost of being stateful across calls.
+	JitterDecorrelated
+)
+
+// JitteredExponentialBackoff is a retry strategy that waits an
+// exponentially increasing amount of time between retries, randomized
+// according to mode to avoid synchronized retries across clients.
+type JitteredExponentialBackoff struct {
+	base     time.Duration
+	maxDelay time.Duration
+	attempts int
+	mode     JitterMode
+	rand     *rand.Rand
+	last     *time.Duration
+}
+
+// NewJitteredExponentialBackoff creates a new JitteredExponentialBackoff
+// strategy using a randomly seeded source.
+func NewJitteredExponentialBackoff(base, maxDelay time.Duration, attempts int, mode JitterMode) JitteredExponentialBackoff {
+	return newJitteredExponentialBackoff(base, maxDelay, attempts, mode, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// NewJitteredExponentialBackoffWithSource creates a new
+// JitteredExponentialBackoff strategy using the given random source,
+// useful for deterministic tests or to avoid contention on the shared
+// global rand lock.
+func NewJitteredExponentialBackoffWithSource(base, maxDelay time.Duration, attempts int, mode JitterMode, src rand.Source) JitteredExponentialBackoff {
+	return newJitteredExponentialBackoff(base, maxDelay, attempts, mode, rand.New(src))
+}
+
+func newJitteredExponentialBackoff(base, maxDelay time.Duration, attempts int, mode JitterMode, rnd *rand.Rand) JitteredExponentialBackoff {
+	last := base
+	return JitteredExponentialBackoff{
+		base:     base,
+		maxDelay: maxDelay,
+		attempts: attempts,
+		mode:     mode,
+		rand:     rnd,
+		last:     &last,
+	}
+}
+
+func (b JitteredExponentialBackoff) GetDelay(attempt int) time.Duration {
+	switch b.mode {
+	case JitterFull:
+		capped := capDelay(time.Duration(1<<attempt)*b.base, b.maxDelay)
+		if capped <= 0 {
+			return 0
+		}
+		return time.Duration(b.rand.Int63n(int64(capped)))
+	case JitterEqual:
+		capped := capDelay(time.Duration(1<<attempt)*b.base, b.maxDelay)
+		half := capped / 2
+		if half <= 0 {
+			return half
+		}
+		return half + time.Duration(b.rand.Int63n(int64(half)))
+	case JitterDecorrelated:
+		upper := 3*(*b.last) - b.base
+		if upper <= 0 {
+			upper = 1
+		}
+		next := capDelay(b.base+time.Duration(b.rand.Int63n(int64(upper))), b.maxDelay)
+		*b.last = next
+		return next
+	default:
+		return capDelay(time.Duration(1<<attempt)*b.base, b.maxDelay)
+	}
+}
+
+func (b JitteredExponentialBackoff) GetAttempts() int {
+	return b.attempts
+}
+
+// Reset clears the delay tracked for JitterDecorrelated back to base, so
+// the strategy can be reused for a new, independent operation.
+func (b JitteredExponentialBackoff) Reset() {
+	*b.last = b.base
+}
+
 // ConstantBackoff is a retry strategy that waits a
 // fixed amount of time between retries.
 type ConstantBackoff struct {
+	NoopReset
 	retryDelay time.Duration
 	attempts   int
 }
@@ -118,23 +297,221 @@ func (b ConstantBackoff) GetAttempts() int {
 	return b.attempts
 }
 
+// ExponentialBackoffWithMaxElapsed is a retry strategy that waits an
+// exponentially increasing amount of time between retries and also gives
+// up once maxElapsed has passed since the first attempt.
+type ExponentialBackoffWithMaxElapsed struct {
+	base       time.Duration
+	maxDelay   time.Duration
+	maxElapsed time.Duration
+	attempts   int
+	start      *time.Time
+}
+
+// NewExponentialBackoffWithMaxElapsed creates a new
+// ExponentialBackoffWithMaxElapsed strategy.
+func NewExponentialBackoffWithMaxElapsed(base, maxDelay, maxElapsed time.Duration, attempts int) ExponentialBackoffWithMaxElapsed {
+	return ExponentialBackoffWithMaxElapsed{
+		base:       base,
+		maxDelay:   maxDelay,
+		maxElapsed: maxElapsed,
+		attempts:   attempts,
+		start:      new(time.Time),
+	}
+}
+
+func (b ExponentialBackoffWithMaxElapsed) GetDelay(attempt int) time.Duration {
+	return capDelay(time.Duration(1<<attempt)*b.base, b.maxDelay)
+}
+
+// GetAttempts returns the configured number of attempts, or 0 once
+// maxElapsed has passed since the first attempt, forcing the retry loop
+// to give up regardless of how many attempts remain.
+func (b ExponentialBackoffWithMaxElapsed) GetAttempts() int {
+	if b.start.IsZero() {
+		*b.start = time.Now()
+	}
+	if b.maxElapsed > 0 && time.Since(*b.start) >= b.maxElapsed {
+		return 0
+	}
+	return b.attempts
+}
+
+// Reset clears the elapsed-time budget so the strategy can be reused for
+// a new, independent operation.
+func (b ExponentialBackoffWithMaxElapsed) Reset() {
+	*b.start = time.Time{}
+}
+
 // Do retries a function until it returns nil or a fatal error.
 // The function will be retried according to the retry strategy.
-func Do(f func() error, rs retryStrategy) (attempts int, err error) {
-	maxAttempts := rs.GetAttempts()
-	for {
-		if err = attemptRetry(f, rs, attempts); err == nil {
-			return attempts + 1, nil
+//
+// Options such as OnRetry and OnGiveUp can be passed to observe failed
+// attempts, e.g. for logging or metrics.
+func Do(f func() error, rs retryStrategy, opts ...Option) (attempts int, err error) {
+	return DoWithContext(context.Background(), func(context.Context) error {
+		return f()
+	}, rs, opts...)
+}
+
+// DoWithContext retries f until it returns nil, a fatal error, or ctx is
+// done. A timer backs the inter-attempt wait instead of time.Sleep, so a
+// canceled or expired context stops the loop immediately; the returned
+// error wraps ctx.Err() and includes the message of the last error from f
+// (that error itself is not wrapped).
+func DoWithContext(ctx context.Context, f func(ctx context.Context) error, rs retryStrategy, opts ...Option) (attempts int, err error) {
+	_, attempts, err = DoWithDataContext(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	}, rs, opts...)
+	return attempts, err
+}
+
+// DoWithData retries f until it returns a nil error or a fatal error,
+// returning the value produced by the successful attempt, e.g.:
+//
+//	body, attempts, err := retry.DoWithData(func() ([]byte, error) {
+//		return doHTTPRequest()
+//	}, strategy)
+func DoWithData[T any](f func() (T, error), rs retryStrategy, opts ...Option) (data T, attempts int, err error) {
+	return DoWithDataContext(context.Background(), func(context.Context) (T, error) {
+		return f()
+	}, rs, opts...)
+}
+
+// DoWithDataContext is the context-aware, value-returning counterpart of
+// DoWithContext and DoWithData combined. Do, DoWithContext and DoWithData
+// are all implemented in terms of it.
+func DoWithDataContext[T any](ctx context.Context, f func(ctx context.Context) (T, error), rs retryStrategy, opts ...Option) (data T, attempts int, err error) {
+	r := New(ctx, rs, opts...)
+	for r.Next() {
+		attemptData, attemptErr := f(ctx)
+		if attemptErr == nil {
+			return attemptData, r.AttemptNumber(), nil
+		}
+		r.SetError(attemptErr)
+	}
+	return data, r.AttemptNumber(), r.Err()
+}
+
+// Retrier drives a retry loop one attempt at a time, as an alternative to
+// Do. Use New to create one. The typical shape is:
+//
+//	r := retry.New(ctx, strategy)
+//	for r.Next() {
+//		if err := op(); err == nil {
+//			break
+//		} else {
+//			r.SetError(err)
+//		}
+//	}
+//	if err := r.Err(); err != nil {
+//		// out of attempts, or a fatal/non-retryable error
+//	}
+type Retrier struct {
+	ctx      context.Context
+	rs       retryStrategy
+	opts     *options
+	attempts int
+	err      error
+	started  bool
+}
+
+// New creates a Retrier that retries according to rs until ctx is done,
+// rs's attempts are exhausted, or a fatal/non-retryable error is set via
+// SetError, whichever comes first.
+func New(ctx context.Context, rs retryStrategy, opts ...Option) *Retrier {
+	return &Retrier{
+		ctx:  ctx,
+		rs:   rs,
+		opts: newOptions(opts),
+	}
+}
+
+// Next reports whether another attempt should be made, waiting for the
+// strategy's delay (or ctx being done) before doing so. Call SetError
+// after each failed attempt so Next can decide whether to continue.
+func (r *Retrier) Next() bool {
+	if r.started {
+		if fatalErr := checkFatal(r.err); fatalErr != nil {
+			r.err = fatalErr
+			r.fireGiveUp()
+			return false
 		}
-		if fatalErr := checkFatal(err); fatalErr != nil {
-			return attempts + 1, fatalErr
+		if r.opts.retryable != nil && !r.opts.retryable(r.err) {
+			r.fireGiveUp()
+			return false
 		}
-		attempts++
-		if shouldStopRetry(attempts, maxAttempts) {
-			break
+	}
+	// GetAttempts is consulted on every call, including the very first, so
+	// a time-budgeted strategy such as ExponentialBackoffWithMaxElapsed
+	// starts its clock at the first attempt rather than after it. Its
+	// result only gates subsequent attempts though: the first attempt
+	// always runs, regardless of what GetAttempts reports.
+	maxAttempts := r.rs.GetAttempts()
+	if r.started && shouldStopRetry(r.attempts, maxAttempts) {
+		r.fireGiveUp()
+		return false
+	}
+	if r.started {
+		delay := r.rs.GetDelay(r.attempts)
+		if r.opts.onRetry != nil {
+			r.opts.onRetry(r.attempts, r.err, delay)
+		}
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-r.ctx.Done():
+			r.err = fmt.Errorf("%w: last error: %v", r.ctx.Err(), r.err)
+			r.fireGiveUp()
+			return false
+		case <-timer.C:
 		}
 	}
-	return attempts, err
+	r.started = true
+	if ctxErr := r.ctx.Err(); ctxErr != nil {
+		r.err = fmt.Errorf("%w: last error: %v", ctxErr, r.err)
+		r.fireGiveUp()
+		return false
+	}
+	r.attempts++
+	r.err = nil
+	return true
+}
+
+// fireGiveUp invokes the OnGiveUp hook, if any, with the current attempt
+// number and error.
+func (r *Retrier) fireGiveUp() {
+	if r.opts.onGiveUp != nil {
+		r.opts.onGiveUp(r.attempts, r.err)
+	}
+}
+
+// SetError records the error from the attempt that was just made. It
+// must be called before the next call to Next, unless the attempt
+// succeeded and the loop is about to exit.
+func (r *Retrier) SetError(err error) {
+	r.err = err
+}
+
+// AttemptNumber returns the number of the attempt currently in progress
+// or, once the loop has ended, the number of the last attempt made.
+func (r *Retrier) AttemptNumber() int {
+	return r.attempts
+}
+
+// MaxAttempts returns the maximum number of attempts currently dictated
+// by the retry strategy, or -1 if it allows infinite retries. For
+// strategies with a time-based budget, this can decrease between calls.
+func (r *Retrier) MaxAttempts() int {
+	return r.rs.GetAttempts()
+}
+
+// Err returns the error set by SetError since the last call to Next (nil
+// if none was set, as Next clears it before each attempt), or the error
+// that made the retry loop give up (context cancellation, a fatal error,
+// or a non-retryable error).
+func (r *Retrier) Err() error {
+	return r.err
 }
 
 // capDelay ensures the delay does not exceed the max limit.
@@ -171,14 +548,6 @@ func shouldStopRetry(attempts, maxAttempts int) bool {
 	return maxAttempts != -1 && attempts >= maxAttempts
 }
 
-// attemptRetry retries a function according to the retry strategy.
-func attemptRetry(f func() error, rs retryStrategy, attempts int) error {
-	if attempts > 0 {
-		time.Sleep(rs.GetDelay(attempts))
-	}
-	return f()
-}
-
 // checkFatal checks if the error is a fatal error.
 func checkFatal(err error) error {
 	var fatalErr *fatal
@@ -5,7 +5,9 @@
 package retry
 
 import (
+	"context"
 	"errors"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -26,6 +28,8 @@ func (m *mockStrategy) GetAttempts() int {
 	return m.attempts
 }
 
+func (m *mockStrategy) Reset() {}
+
 func TestDo(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -104,6 +108,254 @@ func TestDo(t *testing.T) {
 	}
 }
 
+func TestDoWithContext(t *testing.T) {
+	t.Run("succeeds before context is canceled", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 3}
+		attempts, err := DoWithContext(context.Background(), func(ctx context.Context) error {
+			return nil
+		}, strategy)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("canceled context stops retries immediately", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Hour}, attempts: 5}
+		_, err := DoWithContext(ctx, func(ctx context.Context) error {
+			cancel()
+			return errors.New("retryable error")
+		}, strategy)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to wrap context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("deadline exceeded while waiting for next attempt", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Hour}, attempts: 5}
+		_, err := DoWithContext(ctx, func(ctx context.Context) error {
+			return errors.New("retryable error")
+		}, strategy)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestDoWithData(t *testing.T) {
+	t.Run("returns the value produced by the successful attempt", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 3}
+		count := 0
+		data, attempts, err := DoWithData(func() (string, error) {
+			if count < 1 {
+				count++
+				return "", errors.New("retryable error")
+			}
+			return "result", nil
+		}, strategy)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if data != "result" {
+			t.Errorf("expected data %q, got %q", "result", data)
+		}
+		if attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("fatal error stops retries and returns zero value", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0}, attempts: 3}
+		data, attempts, err := DoWithData(func() (int, error) {
+			return 42, EndRetry(errors.New("fatal error"))
+		}, strategy)
+		if err == nil || err.Error() != "fatal error" {
+			t.Errorf("expected fatal error, got %v", err)
+		}
+		if data != 0 {
+			t.Errorf("expected zero value, got %d", data)
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestOnRetryAndOnGiveUp(t *testing.T) {
+	t.Run("OnRetry fires for every failed attempt that is retried, with the next delay", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, 5 * time.Millisecond, 10 * time.Millisecond}, attempts: 3}
+		var retries []time.Duration
+		_, _ = Do(func() error {
+			return errors.New("retryable error")
+		}, strategy, OnRetry(func(attempt int, err error, nextDelay time.Duration) {
+			retries = append(retries, nextDelay)
+		}))
+		expected := []time.Duration{5 * time.Millisecond, 10 * time.Millisecond}
+		if len(retries) != len(expected) {
+			t.Fatalf("expected %d OnRetry calls, got %d", len(expected), len(retries))
+		}
+		for i, d := range expected {
+			if retries[i] != d {
+				t.Errorf("expected delay %v at index %d, got %v", d, i, retries[i])
+			}
+		}
+	})
+
+	t.Run("OnGiveUp fires once retries are exhausted", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 2}
+		var gaveUpAttempts int
+		var gaveUpErr error
+		attempts, err := Do(func() error {
+			return errors.New("retryable error")
+		}, strategy, OnGiveUp(func(attempt int, err error) {
+			gaveUpAttempts = attempt
+			gaveUpErr = err
+		}))
+		if gaveUpAttempts != attempts {
+			t.Errorf("expected OnGiveUp attempt %d, got %d", attempts, gaveUpAttempts)
+		}
+		if gaveUpErr == nil || gaveUpErr.Error() != err.Error() {
+			t.Errorf("expected OnGiveUp error %v, got %v", err, gaveUpErr)
+		}
+	})
+
+	t.Run("OnGiveUp fires when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Hour}, attempts: 5}
+		var gaveUp bool
+		_, err := DoWithContext(ctx, func(ctx context.Context) error {
+			cancel()
+			return errors.New("retryable error")
+		}, strategy, OnGiveUp(func(attempt int, err error) {
+			gaveUp = true
+		}))
+		if !gaveUp {
+			t.Error("expected OnGiveUp to fire when the context is canceled")
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected error to wrap context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestRetryable(t *testing.T) {
+	errNonRetryable := errors.New("non-retryable error")
+
+	t.Run("RetryIf stops retries immediately when predicate returns false", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 5}
+		calls := 0
+		attempts, err := Do(func() error {
+			calls++
+			return errNonRetryable
+		}, strategy, RetryIf(func(err error) bool {
+			return err != errNonRetryable
+		}))
+		if !errors.Is(err, errNonRetryable) {
+			t.Errorf("expected error %v, got %v", errNonRetryable, err)
+		}
+		if attempts != 1 || calls != 1 {
+			t.Errorf("expected a single attempt, got attempts=%d calls=%d", attempts, calls)
+		}
+	})
+
+	t.Run("AbortIf is the inverse of RetryIf", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 5}
+		calls := 0
+		attempts, err := Do(func() error {
+			calls++
+			return errNonRetryable
+		}, strategy, AbortIf(func(err error) bool {
+			return err == errNonRetryable
+		}))
+		if !errors.Is(err, errNonRetryable) {
+			t.Errorf("expected error %v, got %v", errNonRetryable, err)
+		}
+		if attempts != 1 || calls != 1 {
+			t.Errorf("expected a single attempt, got attempts=%d calls=%d", attempts, calls)
+		}
+	})
+
+	t.Run("fatal error wins over a retryable predicate", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 5}
+		attempts, err := Do(func() error {
+			return EndRetry(errNonRetryable)
+		}, strategy, RetryIf(func(err error) bool {
+			return true
+		}))
+		if !errors.Is(err, errNonRetryable) {
+			t.Errorf("expected error %v, got %v", errNonRetryable, err)
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestRetrier(t *testing.T) {
+	t.Run("retries until success, tracking attempt number", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond, time.Millisecond}, attempts: 5}
+		r := New(context.Background(), strategy)
+		count := 0
+		for r.Next() {
+			count++
+			if count < 3 {
+				r.SetError(errors.New("retryable error"))
+				continue
+			}
+			break
+		}
+		if err := r.Err(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if r.AttemptNumber() != 3 {
+			t.Errorf("expected attempt number 3, got %d", r.AttemptNumber())
+		}
+		if r.MaxAttempts() != 5 {
+			t.Errorf("expected max attempts 5, got %d", r.MaxAttempts())
+		}
+	})
+
+	t.Run("stops once attempts are exhausted", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 2}
+		r := New(context.Background(), strategy)
+		attempts := 0
+		for r.Next() {
+			attempts++
+			r.SetError(errors.New("retryable error"))
+		}
+		if attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", attempts)
+		}
+		if r.AttemptNumber() != 2 {
+			t.Errorf("expected attempt number 2, got %d", r.AttemptNumber())
+		}
+		if r.Err() == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("a fatal error stops retries immediately", func(t *testing.T) {
+		strategy := &mockStrategy{delays: []time.Duration{0, time.Millisecond}, attempts: 5}
+		r := New(context.Background(), strategy)
+		attempts := 0
+		for r.Next() {
+			attempts++
+			r.SetError(EndRetry(errors.New("fatal error")))
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+		if err := r.Err(); err == nil || err.Error() != "fatal error" {
+			t.Errorf("expected fatal error, got %v", err)
+		}
+	})
+}
+
 func TestCapDelay(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -162,6 +414,22 @@ func TestBackoffImplementations(t *testing.T) {
 			name:     "ConstantBackoff",
 			strategy: NewConstantBackoff(100*time.Millisecond, 5),
 		},
+		{
+			name:     "JitteredExponentialBackoff/Full",
+			strategy: NewJitteredExponentialBackoffWithSource(100*time.Millisecond, 1*time.Second, 5, JitterFull, rand.NewSource(1)),
+		},
+		{
+			name:     "JitteredExponentialBackoff/Equal",
+			strategy: NewJitteredExponentialBackoffWithSource(100*time.Millisecond, 1*time.Second, 5, JitterEqual, rand.NewSource(1)),
+		},
+		{
+			name:     "JitteredExponentialBackoff/Decorrelated",
+			strategy: NewJitteredExponentialBackoffWithSource(100*time.Millisecond, 1*time.Second, 5, JitterDecorrelated, rand.NewSource(1)),
+		},
+		{
+			name:     "JitteredExponentialBackoff/None",
+			strategy: NewJitteredExponentialBackoffWithSource(100*time.Millisecond, 1*time.Second, 5, JitterNone, rand.NewSource(1)),
+		},
 	}
 
 	for _, tt := range strategies {
@@ -176,6 +444,125 @@ func TestBackoffImplementations(t *testing.T) {
 	}
 }
 
+func TestJitteredExponentialBackoff(t *testing.T) {
+	const (
+		base = 100 * time.Millisecond
+		max  = 2 * time.Second
+	)
+
+	t.Run("JitterNone matches plain exponential growth", func(t *testing.T) {
+		strategy := NewJitteredExponentialBackoffWithSource(base, max, 5, JitterNone, rand.NewSource(1))
+		for attempt := 0; attempt < 5; attempt++ {
+			expected := capDelay(time.Duration(1<<attempt)*base, max)
+			if delay := strategy.GetDelay(attempt); delay != expected {
+				t.Errorf("attempt %d: expected %v, got %v", attempt, expected, delay)
+			}
+		}
+	})
+
+	t.Run("JitterFull stays within [0, capped exponential delay)", func(t *testing.T) {
+		strategy := NewJitteredExponentialBackoffWithSource(base, max, 5, JitterFull, rand.NewSource(1))
+		for attempt := 0; attempt < 5; attempt++ {
+			capped := capDelay(time.Duration(1<<attempt)*base, max)
+			delay := strategy.GetDelay(attempt)
+			if delay < 0 || delay >= capped {
+				t.Errorf("attempt %d: delay %v out of [0, %v)", attempt, delay, capped)
+			}
+		}
+	})
+
+	t.Run("JitterEqual stays within [half, capped exponential delay)", func(t *testing.T) {
+		strategy := NewJitteredExponentialBackoffWithSource(base, max, 5, JitterEqual, rand.NewSource(1))
+		for attempt := 0; attempt < 5; attempt++ {
+			capped := capDelay(time.Duration(1<<attempt)*base, max)
+			half := capped / 2
+			delay := strategy.GetDelay(attempt)
+			if delay < half || delay >= capped {
+				t.Errorf("attempt %d: delay %v out of [%v, %v)", attempt, delay, half, capped)
+			}
+		}
+	})
+
+	t.Run("JitterDecorrelated never exceeds max", func(t *testing.T) {
+		strategy := NewJitteredExponentialBackoffWithSource(base, max, 10, JitterDecorrelated, rand.NewSource(1))
+		for attempt := 0; attempt < 10; attempt++ {
+			if delay := strategy.GetDelay(attempt); delay < 0 || delay > max {
+				t.Errorf("attempt %d: delay %v out of [0, %v]", attempt, delay, max)
+			}
+		}
+	})
+
+	t.Run("JitterDecorrelated does not panic when base is zero", func(t *testing.T) {
+		strategy := NewJitteredExponentialBackoffWithSource(0, max, 10, JitterDecorrelated, rand.NewSource(1))
+		for attempt := 0; attempt < 10; attempt++ {
+			if delay := strategy.GetDelay(attempt); delay < 0 || delay > max {
+				t.Errorf("attempt %d: delay %v out of [0, %v]", attempt, delay, max)
+			}
+		}
+	})
+}
+
+func TestExponentialBackoffWithMaxElapsed(t *testing.T) {
+	t.Run("GetAttempts returns 0 once maxElapsed has passed", func(t *testing.T) {
+		strategy := NewExponentialBackoffWithMaxElapsed(time.Millisecond, 10*time.Millisecond, 10*time.Millisecond, 100)
+		if attempts := strategy.GetAttempts(); attempts != 100 {
+			t.Fatalf("expected 100 attempts right after creation, got %d", attempts)
+		}
+		time.Sleep(15 * time.Millisecond)
+		if attempts := strategy.GetAttempts(); attempts != 0 {
+			t.Errorf("expected 0 attempts after maxElapsed has passed, got %d", attempts)
+		}
+	})
+
+	t.Run("Reset restarts the elapsed-time budget", func(t *testing.T) {
+		strategy := NewExponentialBackoffWithMaxElapsed(time.Millisecond, 10*time.Millisecond, 10*time.Millisecond, 100)
+		strategy.GetAttempts()
+		time.Sleep(15 * time.Millisecond)
+		if attempts := strategy.GetAttempts(); attempts != 0 {
+			t.Fatalf("expected 0 attempts after maxElapsed has passed, got %d", attempts)
+		}
+		strategy.Reset()
+		if attempts := strategy.GetAttempts(); attempts != 100 {
+			t.Errorf("expected 100 attempts right after Reset, got %d", attempts)
+		}
+	})
+
+	t.Run("Do gives up once the elapsed-time budget is exhausted", func(t *testing.T) {
+		strategy := NewExponentialBackoffWithMaxElapsed(time.Millisecond, time.Millisecond, 5*time.Millisecond, 1000)
+		calls := 0
+		_, err := Do(func() error {
+			calls++
+			return errors.New("retryable error")
+		}, strategy)
+		if err == nil {
+			t.Fatal("expected an error once the time budget is exhausted")
+		}
+		if calls >= 1000 {
+			t.Errorf("expected far fewer than 1000 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("Do still makes the first attempt when the budget from a previous run was never Reset", func(t *testing.T) {
+		strategy := NewExponentialBackoffWithMaxElapsed(time.Millisecond, time.Millisecond, 5*time.Millisecond, 1000)
+		_, _ = Do(func() error {
+			return errors.New("retryable error")
+		}, strategy)
+		time.Sleep(10 * time.Millisecond)
+
+		calls := 0
+		attempts, err := Do(func() error {
+			calls++
+			return nil
+		}, strategy)
+		if calls != 1 {
+			t.Errorf("expected the function to be called once, got %d calls", calls)
+		}
+		if attempts != 1 || err != nil {
+			t.Errorf("expected (1, nil), got (%d, %v)", attempts, err)
+		}
+	})
+}
+
 func TestFatalError(t *testing.T) {
 	expectedMessage := "fatal error occurred"
 	fatalErr := &fatal{cause: errors.New(expectedMessage)}